@@ -0,0 +1,29 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/geobobchieke/sre-tech/Deployments/go/internal/store"
+)
+
+func TestErrorReason(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"conflict", fmt.Errorf("%w: duplicate key", store.ErrConflict), "db_conflict"},
+		{"timeout", fmt.Errorf("%w: context deadline exceeded", store.ErrTimeout), "db_timeout"},
+		{"unrecognized", errors.New("connection refused"), "db_other"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := errorReason(tc.err); got != tc.want {
+				t.Errorf("errorReason(%v) = %q, want %q", tc.err, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/geobobchieke/sre-tech/Deployments/go/internal/metrics"
+)
+
+// TestMetricsMiddlewareCollapsesPathTemplate verifies that per-ID routes like
+// /transactions/{id} collapse into a single histogram/counter series instead
+// of one series per ID.
+func TestMetricsMiddlewareCollapsesPathTemplate(t *testing.T) {
+	a := NewWithConfig(nil, metrics.New(metrics.Config{}), DefaultMetricsConfig())
+
+	r := mux.NewRouter()
+	r.Use(a.metricsMiddleware)
+	r.HandleFunc("/transactions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}).Methods("GET")
+
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	for _, id := range []string{"1", "2", "3"} {
+		resp, err := http.Get(srv.URL + "/transactions/" + id)
+		if err != nil {
+			t.Fatalf("GET /transactions/%s failed: %v", id, err)
+		}
+		resp.Body.Close()
+	}
+
+	body := scrapeMetrics(t, a)
+
+	const wantSeries = `http_requests_total{method="GET",path="/transactions/{id}",status_code="200"} 3`
+	if !strings.Contains(body, wantSeries) {
+		t.Fatalf("expected one collapsed series %q, got:\n%s", wantSeries, body)
+	}
+
+	if got := strings.Count(body, `http_requests_total{method="GET",path="/transactions/`); got != 1 {
+		t.Fatalf("expected exactly 1 http_requests_total series for /transactions/{id}, got %d\n%s", got, body)
+	}
+}
+
+// TestRouteSizeBucketsRegisterDistinctSeries verifies that a RouteSizeBuckets
+// override actually registers and produces scrapeable series, instead of
+// silently failing registration because it shares a metric name with the
+// base histogram.
+func TestRouteSizeBucketsRegisterDistinctSeries(t *testing.T) {
+	cfg := DefaultMetricsConfig()
+	cfg.RouteSizeBuckets = map[string][]float64{
+		"/transactions": {1000, 10000, 100000},
+	}
+	a := NewWithConfig(nil, metrics.New(metrics.Config{}), cfg)
+
+	r := mux.NewRouter()
+	r.Use(a.metricsMiddleware)
+	r.HandleFunc("/transactions", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}).Methods("POST")
+
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/transactions", "application/json", strings.NewReader(`{"value":1}`))
+	if err != nil {
+		t.Fatalf("POST /transactions failed: %v", err)
+	}
+	resp.Body.Close()
+
+	body := scrapeMetrics(t, a)
+
+	const wantMetric = "http_request_size_bytes_route_transactions"
+	if !strings.Contains(body, wantMetric) {
+		t.Fatalf("expected route-specific metric %q to be registered and scraped, got:\n%s", wantMetric, body)
+	}
+}
+
+func scrapeMetrics(t *testing.T, a *App) string {
+	t.Helper()
+
+	rr := httptest.NewRecorder()
+	a.metrics.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return rr.Body.String()
+}
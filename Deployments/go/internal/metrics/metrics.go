@@ -0,0 +1,181 @@
+// Package metrics owns this service's Prometheus registry. Callers get a
+// Registerer to hang their own collectors off of instead of reaching for
+// prometheus.DefaultRegisterer, which keeps App free of package-level
+// registration and therefore testable.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Config controls the metrics subsystem.
+type Config struct {
+	// SlowSQLThreshold is the duration above which a SQL call is logged and
+	// counted as slow. Zero disables slow-query logging (every query is
+	// still observed in the duration histogram).
+	SlowSQLThreshold time.Duration
+}
+
+// Metrics owns a dedicated prometheus.Registry and the collectors shared
+// across the DB and HTTP layers.
+type Metrics struct {
+	registry *prometheus.Registry
+	cfg      Config
+
+	sqlQueryDuration *prometheus.HistogramVec
+	sqlSlowQueries   *prometheus.CounterVec
+}
+
+// New creates a Metrics instance with its own registry (not the global
+// prometheus.DefaultRegisterer) and registers the Go runtime and process
+// collectors on it.
+func New(cfg Config) *Metrics {
+	registry := prometheus.NewRegistry()
+
+	m := &Metrics{
+		registry: registry,
+		cfg:      cfg,
+		sqlQueryDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "sql_query_duration_seconds",
+				Help:    "Duration of SQL calls made by the service.",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"op", "table"},
+		),
+		sqlSlowQueries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "sql_slow_queries_total",
+				Help: "Number of SQL calls that exceeded the slow-SQL threshold.",
+			},
+			[]string{"op", "table"},
+		),
+	}
+
+	registry.MustRegister(m.sqlQueryDuration)
+	registry.MustRegister(m.sqlSlowQueries)
+	registry.MustRegister(prometheus.NewGoCollector())
+	registry.MustRegister(prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+
+	return m
+}
+
+// Registerer exposes the metrics registry to the DB and HTTP layers so they
+// can register their own collectors without touching prometheus.DefaultRegisterer.
+func (m *Metrics) Registerer() prometheus.Registerer {
+	return m.registry
+}
+
+// Handler serves this service's metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// tableNameRE pulls the table name out of a FROM/INTO/UPDATE clause so slow
+// queries can be attributed without a full SQL parser.
+var tableNameRE = regexp.MustCompile(`(?i)\b(?:FROM|INTO|UPDATE)\s+([a-zA-Z_][a-zA-Z0-9_]*)`)
+
+func tableFromQuery(query string) string {
+	if match := tableNameRE.FindStringSubmatch(query); match != nil {
+		return strings.ToLower(match[1])
+	}
+	return "unknown"
+}
+
+// TracedDB wraps a *sql.DB so every Query/Exec/QueryRow call is timed,
+// recorded in the sql_query_duration_seconds histogram, and, when it exceeds
+// SlowSQLThreshold, logged and counted in sql_slow_queries_total.
+type TracedDB struct {
+	db *sql.DB
+	m  *Metrics
+}
+
+// WrapDB instruments db with slow-SQL tracing backed by m.
+func (m *Metrics) WrapDB(db *sql.DB) *TracedDB {
+	return &TracedDB{db: db, m: m}
+}
+
+func (t *TracedDB) observe(op, query string, start time.Time) {
+	table := tableFromQuery(query)
+	duration := time.Since(start)
+
+	t.m.sqlQueryDuration.WithLabelValues(op, table).Observe(duration.Seconds())
+
+	if t.m.cfg.SlowSQLThreshold > 0 && duration > t.m.cfg.SlowSQLThreshold {
+		t.m.sqlSlowQueries.WithLabelValues(op, table).Inc()
+		log.Printf("slow SQL query (%s on %s took %s): %s", op, table, duration, query)
+	}
+}
+
+// Query runs db.Query and records it as a "query" op.
+func (t *TracedDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return t.QueryContext(context.Background(), query, args...)
+}
+
+// QueryContext runs db.QueryContext and records it as a "query" op. ctx
+// governs cancellation of the underlying call, so a caller's deadline or
+// cancellation actually aborts the in-flight query instead of only being
+// checked after it returns.
+func (t *TracedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := t.db.QueryContext(ctx, query, args...)
+	t.observe("query", query, start)
+	return rows, err
+}
+
+// QueryRow runs db.QueryRow and records it as a "query_row" op.
+func (t *TracedDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return t.QueryRowContext(context.Background(), query, args...)
+}
+
+// QueryRowContext runs db.QueryRowContext and records it as a "query_row" op.
+// ctx governs cancellation of the underlying call.
+func (t *TracedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := t.db.QueryRowContext(ctx, query, args...)
+	t.observe("query_row", query, start)
+	return row
+}
+
+// Exec runs db.Exec and records it as an "exec" op.
+func (t *TracedDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return t.ExecContext(context.Background(), query, args...)
+}
+
+// ExecContext runs db.ExecContext and records it as an "exec" op. ctx governs
+// cancellation of the underlying call.
+func (t *TracedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	res, err := t.db.ExecContext(ctx, query, args...)
+	t.observe("exec", query, start)
+	return res, err
+}
+
+// Ping proxies to the underlying *sql.DB.
+func (t *TracedDB) Ping() error {
+	return t.db.Ping()
+}
+
+// PingContext proxies to the underlying *sql.DB, honoring ctx's cancellation.
+func (t *TracedDB) PingContext(ctx context.Context) error {
+	return t.db.PingContext(ctx)
+}
+
+// Stats proxies to the underlying *sql.DB.
+func (t *TracedDB) Stats() sql.DBStats {
+	return t.db.Stats()
+}
+
+// Close proxies to the underlying *sql.DB.
+func (t *TracedDB) Close() error {
+	return t.db.Close()
+}
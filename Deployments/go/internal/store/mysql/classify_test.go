@@ -0,0 +1,70 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/geobobchieke/sre-tech/Deployments/go/internal/store"
+)
+
+// timeoutErr stands in for a driver-level net.Error timeout.
+type timeoutErr struct{ error }
+
+func (timeoutErr) Timeout() bool { return true }
+
+func TestClassifyErr(t *testing.T) {
+	cases := []struct {
+		name    string
+		err     error
+		wantNil bool
+		wantAs  error
+	}{
+		{name: "nil", err: nil, wantNil: true},
+		{
+			name:   "duplicate_entry",
+			err:    &mysql.MySQLError{Number: errDuplicateEntry, Message: "Duplicate entry '1' for key 'PRIMARY'"},
+			wantAs: store.ErrConflict,
+		},
+		{
+			name:   "deadline_exceeded",
+			err:    context.DeadlineExceeded,
+			wantAs: store.ErrTimeout,
+		},
+		{
+			name:   "net_timeout",
+			err:    timeoutErr{errors.New("i/o timeout")},
+			wantAs: store.ErrTimeout,
+		},
+		{
+			name: "unrecognized",
+			err:  &mysql.MySQLError{Number: 1045, Message: "Access denied"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := classifyErr(tc.err)
+
+			if tc.wantNil {
+				if got != nil {
+					t.Fatalf("classifyErr(nil) = %v, want nil", got)
+				}
+				return
+			}
+
+			if tc.wantAs != nil {
+				if !errors.Is(got, tc.wantAs) {
+					t.Fatalf("classifyErr(%v) = %v, want errors.Is match for %v", tc.err, got, tc.wantAs)
+				}
+				return
+			}
+
+			if got != tc.err {
+				t.Fatalf("classifyErr(%v) = %v, want unchanged", tc.err, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+// Package postgres is the store.Backend driver for postgres:// DATABASE_URLs.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/geobobchieke/sre-tech/Deployments/go/internal/metrics"
+	"github.com/geobobchieke/sre-tech/Deployments/go/internal/store"
+)
+
+// uniqueViolation is the Postgres error code for a unique-constraint
+// violation. See https://www.postgresql.org/docs/current/errcodes-appendix.html.
+const uniqueViolation = "23505"
+
+// classifyErr wraps err with store.ErrConflict or store.ErrTimeout when it
+// recognizes the cause, so the API layer can label it without knowing this
+// is a Postgres-specific error.
+func classifyErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		return fmt.Errorf("%w: %s", store.ErrConflict, pqErr.Message)
+	}
+	if store.IsTimeout(err) {
+		return fmt.Errorf("%w: %v", store.ErrTimeout, err)
+	}
+	return err
+}
+
+func init() {
+	store.Register("postgres", New)
+	store.Register("postgresql", New)
+}
+
+type backend struct {
+	db         *metrics.TracedDB
+	opsCounter *prometheus.CounterVec
+
+	// cancel stops the background metrics collectors started in New. It's
+	// derived from the ctx passed to New, so canceling that ctx also stops
+	// them, but Close doesn't depend on the caller having done so.
+	cancel context.CancelFunc
+}
+
+// New opens a Postgres-backed store.Backend for databaseURL and registers
+// its connection-pool and per-op metrics on m. Its background metrics
+// goroutines stop when ctx is done, or when the returned Backend's Close is
+// called, whichever comes first.
+func New(ctx context.Context, databaseURL string, m *metrics.Metrics) (store.Backend, error) {
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	traced := m.WrapDB(db)
+	collectorCtx, cancel := context.WithCancel(ctx)
+	b := &backend{db: traced, cancel: cancel}
+
+	if err := b.migrate(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	registerer := m.Registerer()
+	store.CollectDBStats(collectorCtx, traced, registerer)
+	store.CollectActiveSources(collectorCtx, traced, registerer,
+		`SELECT COUNT(DISTINCT source) FROM transactions WHERE created_at > NOW() - INTERVAL '1 hour'`)
+	b.opsCounter = store.NewOpsCounter(registerer, "postgres")
+
+	return b, nil
+}
+
+func (b *backend) migrate() error {
+	createTable := `
+	CREATE TABLE IF NOT EXISTS transactions (
+		id SERIAL PRIMARY KEY,
+		value DECIMAL(15,2) NOT NULL,
+		timestamp TIMESTAMP NOT NULL,
+		status VARCHAR(50) DEFAULT 'completed',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	if _, err := b.db.Exec(createTable); err != nil {
+		return err
+	}
+
+	_, err := b.db.Exec(`ALTER TABLE transactions ADD COLUMN IF NOT EXISTS source VARCHAR(255);`)
+	return err
+}
+
+func (b *backend) Create(ctx context.Context, req store.TransactionRequest, source string) (store.Transaction, error) {
+	b.opsCounter.WithLabelValues("create").Inc()
+
+	query := `
+		INSERT INTO transactions (value, timestamp, status, created_at, source)
+		VALUES ($1, $2, 'completed', CURRENT_TIMESTAMP, $3)
+		RETURNING id, value, timestamp, status, created_at`
+
+	var txn store.Transaction
+	err := b.db.QueryRowContext(ctx, query, req.Value, req.Timestamp, source).Scan(
+		&txn.ID, &txn.Value, &txn.Timestamp, &txn.Status, &txn.CreatedAt)
+	return txn, classifyErr(err)
+}
+
+func (b *backend) Get(ctx context.Context, id string) (store.Transaction, error) {
+	b.opsCounter.WithLabelValues("get").Inc()
+
+	query := `
+		SELECT id, value, timestamp, status, created_at
+		FROM transactions
+		WHERE id = $1`
+
+	var txn store.Transaction
+	err := b.db.QueryRowContext(ctx, query, id).Scan(
+		&txn.ID, &txn.Value, &txn.Timestamp, &txn.Status, &txn.CreatedAt)
+	if err == sql.ErrNoRows {
+		return store.Transaction{}, store.ErrNotFound
+	}
+	return txn, err
+}
+
+func (b *backend) List(ctx context.Context, limit, offset int) ([]store.Transaction, error) {
+	b.opsCounter.WithLabelValues("list").Inc()
+
+	query := `
+		SELECT id, value, timestamp, status, created_at
+		FROM transactions
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2`
+
+	rows, err := b.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []store.Transaction
+	for rows.Next() {
+		var txn store.Transaction
+		if err := rows.Scan(&txn.ID, &txn.Value, &txn.Timestamp, &txn.Status, &txn.CreatedAt); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, txn)
+	}
+	return transactions, rows.Err()
+}
+
+func (b *backend) Ping(ctx context.Context) error {
+	b.opsCounter.WithLabelValues("ping").Inc()
+	return b.db.PingContext(ctx)
+}
+
+func (b *backend) Close(ctx context.Context) error {
+	b.cancel()
+	return b.db.Close()
+}
@@ -0,0 +1,163 @@
+// Package sqlite is the store.Backend driver for sqlite:// DATABASE_URLs.
+// It's meant for developer and edge environments that don't have a Postgres
+// or MySQL server available.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/geobobchieke/sre-tech/Deployments/go/internal/metrics"
+	"github.com/geobobchieke/sre-tech/Deployments/go/internal/store"
+)
+
+// classifyErr wraps err with store.ErrConflict or store.ErrTimeout when it
+// recognizes the cause, so the API layer can label it without knowing this
+// is a SQLite-specific error.
+func classifyErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return fmt.Errorf("%w: %s", store.ErrConflict, sqliteErr.Error())
+	}
+	if store.IsTimeout(err) {
+		return fmt.Errorf("%w: %v", store.ErrTimeout, err)
+	}
+	return err
+}
+
+func init() {
+	store.Register("sqlite", New)
+	store.Register("sqlite3", New)
+}
+
+type backend struct {
+	db         *metrics.TracedDB
+	opsCounter *prometheus.CounterVec
+
+	// cancel stops the background metrics collectors started in New. It's
+	// derived from the ctx passed to New, so canceling that ctx also stops
+	// them, but Close doesn't depend on the caller having done so.
+	cancel context.CancelFunc
+}
+
+// New opens a SQLite-backed store.Backend for databaseURL and registers its
+// connection-pool and per-op metrics on m. databaseURL is expected as
+// sqlite://<path>, which is stripped to the file path the driver expects.
+// Its background metrics goroutines stop when ctx is done, or when the
+// returned Backend's Close is called, whichever comes first.
+func New(ctx context.Context, databaseURL string, m *metrics.Metrics) (store.Backend, error) {
+	path := strings.TrimPrefix(databaseURL, "sqlite://")
+	if path == "" {
+		path = ":memory:"
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+
+	traced := m.WrapDB(db)
+	collectorCtx, cancel := context.WithCancel(ctx)
+	b := &backend{db: traced, cancel: cancel}
+
+	if err := b.migrate(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	registerer := m.Registerer()
+	store.CollectDBStats(collectorCtx, traced, registerer)
+	store.CollectActiveSources(collectorCtx, traced, registerer,
+		`SELECT COUNT(DISTINCT source) FROM transactions WHERE created_at > datetime('now', '-1 hour')`)
+	b.opsCounter = store.NewOpsCounter(registerer, "sqlite")
+
+	return b, nil
+}
+
+func (b *backend) migrate() error {
+	createTable := `
+	CREATE TABLE IF NOT EXISTS transactions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		value DECIMAL(15,2) NOT NULL,
+		timestamp DATETIME NOT NULL,
+		status VARCHAR(50) DEFAULT 'completed',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		source VARCHAR(255)
+	);`
+
+	_, err := b.db.Exec(createTable)
+	return err
+}
+
+func (b *backend) Create(ctx context.Context, req store.TransactionRequest, source string) (store.Transaction, error) {
+	b.opsCounter.WithLabelValues("create").Inc()
+
+	res, err := b.db.ExecContext(ctx,
+		`INSERT INTO transactions (value, timestamp, status, created_at, source) VALUES (?, ?, 'completed', CURRENT_TIMESTAMP, ?)`,
+		req.Value, req.Timestamp, source)
+	if err != nil {
+		return store.Transaction{}, classifyErr(err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return store.Transaction{}, err
+	}
+
+	return b.Get(ctx, strconv.FormatInt(id, 10))
+}
+
+func (b *backend) Get(ctx context.Context, id string) (store.Transaction, error) {
+	b.opsCounter.WithLabelValues("get").Inc()
+
+	var txn store.Transaction
+	err := b.db.QueryRowContext(ctx,
+		`SELECT id, value, timestamp, status, created_at FROM transactions WHERE id = ?`, id).Scan(
+		&txn.ID, &txn.Value, &txn.Timestamp, &txn.Status, &txn.CreatedAt)
+	if err == sql.ErrNoRows {
+		return store.Transaction{}, store.ErrNotFound
+	}
+	return txn, err
+}
+
+func (b *backend) List(ctx context.Context, limit, offset int) ([]store.Transaction, error) {
+	b.opsCounter.WithLabelValues("list").Inc()
+
+	rows, err := b.db.QueryContext(ctx,
+		`SELECT id, value, timestamp, status, created_at FROM transactions ORDER BY created_at DESC LIMIT ? OFFSET ?`,
+		limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []store.Transaction
+	for rows.Next() {
+		var txn store.Transaction
+		if err := rows.Scan(&txn.ID, &txn.Value, &txn.Timestamp, &txn.Status, &txn.CreatedAt); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, txn)
+	}
+	return transactions, rows.Err()
+}
+
+func (b *backend) Ping(ctx context.Context) error {
+	b.opsCounter.WithLabelValues("ping").Inc()
+	return b.db.PingContext(ctx)
+}
+
+func (b *backend) Close(ctx context.Context) error {
+	b.cancel()
+	return b.db.Close()
+}
@@ -0,0 +1,208 @@
+// Package store defines the storage backend abstraction used by the
+// transactions API. Concrete drivers (postgres, mysql, sqlite) register
+// themselves here by scheme so the API layer never has to know which
+// database it's talking to.
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/geobobchieke/sre-tech/Deployments/go/internal/metrics"
+)
+
+// ErrNotFound is returned by Backend.Get when no transaction matches the
+// given ID.
+var ErrNotFound = errors.New("store: transaction not found")
+
+// ErrConflict is wrapped around a driver's unique/constraint-violation error
+// so the API layer can classify it as transactions_errors_total{reason="db_conflict"}
+// without knowing which driver is in play.
+var ErrConflict = errors.New("store: conflicting write")
+
+// ErrTimeout is wrapped around a driver's timeout error so the API layer can
+// classify it as transactions_errors_total{reason="db_timeout"}.
+var ErrTimeout = errors.New("store: operation timed out")
+
+// IsTimeout reports whether err represents a timed-out operation, whether
+// that's an exceeded context deadline or a driver-level net.Error timeout.
+// A context canceled without a deadline (context.Canceled) is not a timeout.
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr interface{ Timeout() bool }
+	if errors.As(err, &netErr) {
+		return netErr.Timeout()
+	}
+	return false
+}
+
+type Transaction struct {
+	ID        string    `json:"id" db:"id"`
+	Value     float64   `json:"value" db:"value"`
+	Timestamp time.Time `json:"timestamp" db:"timestamp"`
+	Status    string    `json:"status" db:"status"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type TransactionRequest struct {
+	Value     float64   `json:"value"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Backend is implemented by each storage driver.
+type Backend interface {
+	Create(ctx context.Context, req TransactionRequest, source string) (Transaction, error)
+	Get(ctx context.Context, id string) (Transaction, error)
+	List(ctx context.Context, limit, offset int) ([]Transaction, error)
+	Ping(ctx context.Context) error
+
+	// Close stops the backend's background metrics collectors and closes
+	// its database connection. The drivers in this module ignore ctx;
+	// Close does not block on it.
+	Close(ctx context.Context) error
+}
+
+// Factory opens a Backend for a DATABASE_URL matching the scheme it was
+// registered under. The background goroutines it starts (connection-pool
+// stats, active-sources polling) must stop when ctx is done, or sooner if
+// the returned Backend's Close is called.
+type Factory func(ctx context.Context, databaseURL string, m *metrics.Metrics) (Backend, error)
+
+var factories = map[string]Factory{}
+
+// Register makes a driver available under the given DATABASE_URL scheme.
+// It's meant to be called from a driver package's init(), the same way
+// database/sql drivers register themselves.
+func Register(scheme string, factory Factory) {
+	factories[scheme] = factory
+}
+
+// New opens a Backend for databaseURL by dispatching on its scheme to
+// whichever driver registered it (postgres://, mysql://, sqlite://). The
+// driver package must be blank-imported so its init() has run. ctx governs
+// the backend's background metrics goroutines; cancel it, or call the
+// returned Backend's Close, to stop them.
+func New(ctx context.Context, databaseURL string, m *metrics.Metrics) (Backend, error) {
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("store: invalid DATABASE_URL: %w", err)
+	}
+
+	factory, ok := factories[u.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("store: no driver registered for scheme %q (forgot to import it?)", u.Scheme)
+	}
+
+	return factory(ctx, databaseURL, m)
+}
+
+// RegisterOrLog registers c on registerer, logging (rather than failing)
+// when a collector with the same descriptor is already registered.
+func RegisterOrLog(registerer prometheus.Registerer, c prometheus.Collector) {
+	if err := registerer.Register(c); err != nil {
+		log.Printf("Metrics registration skipped: %v", err)
+	}
+}
+
+// NewOpsCounter returns a db_operations_total counter, labeled by op, with a
+// const "driver" label identifying which backend is doing the counting.
+func NewOpsCounter(registerer prometheus.Registerer, driver string) *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        "db_operations_total",
+			Help:        "Number of storage backend operations, labeled by op.",
+			ConstLabels: prometheus.Labels{"driver": driver},
+		},
+		[]string{"op"},
+	)
+	RegisterOrLog(registerer, c)
+	return c
+}
+
+// CollectDBStats registers connection-pool gauges on registerer and starts a
+// goroutine that keeps them in sync with db.Stats() until ctx is done.
+func CollectDBStats(ctx context.Context, db *metrics.TracedDB, registerer prometheus.Registerer) {
+	dbMaxOpenConns := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "db_max_open_connections",
+		Help: "Maximum number of open connections to the database.",
+	})
+	dbOpenConns := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "db_open_connections",
+		Help: "The number of established connections both in use and idle.",
+	})
+	dbInUseConns := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "db_in_use_connections",
+		Help: "The number of connections currently in use.",
+	})
+	dbIdleConns := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "db_idle_connections",
+		Help: "The number of idle connections.",
+	})
+
+	for _, c := range []prometheus.Collector{dbMaxOpenConns, dbOpenConns, dbInUseConns, dbIdleConns} {
+		RegisterOrLog(registerer, c)
+	}
+
+	go func() {
+		ticker := time.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				stats := db.Stats()
+				dbMaxOpenConns.Set(float64(stats.MaxOpenConnections))
+				dbOpenConns.Set(float64(stats.OpenConnections))
+				dbInUseConns.Set(float64(stats.InUse))
+				dbIdleConns.Set(float64(stats.Idle))
+			}
+		}
+	}()
+}
+
+// CollectActiveSources registers the transactions_active_sources gauge on
+// registerer and starts a goroutine that keeps it in sync with the result of
+// query, which should count distinct transaction sources in the last hour
+// (the exact SQL differs by dialect, hence the caller supplies it), until
+// ctx is done.
+//
+// The gauge is an unlabeled count, not one series per source: source is an
+// caller-supplied X-Client-Id header or a source IP, both unbounded-cardinality
+// values, so labeling by source would let a single caller blow up this
+// metric's series count. See requestSource in internal/api for where source
+// values come from.
+func CollectActiveSources(ctx context.Context, db *metrics.TracedDB, registerer prometheus.Registerer, query string) {
+	activeSources := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "transactions_active_sources",
+		Help: "Number of distinct transaction sources active in the last hour.",
+	})
+
+	RegisterOrLog(registerer, activeSources)
+
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				var count int
+				if err := db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+					log.Printf("Failed to collect active sources: %v", err)
+				} else {
+					activeSources.Set(float64(count))
+				}
+			}
+		}
+	}()
+}